@@ -0,0 +1,38 @@
+package filerotate
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+)
+
+// CompressGzip is the default Compressor, compressing src into dst using
+// gzip at the default compression level.
+func CompressGzip(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %v", src, err)
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %v", dst, err)
+	}
+
+	gz := gzip.NewWriter(out)
+
+	if _, err := io.Copy(gz, in); err != nil {
+		gz.Close()
+		out.Close()
+		return fmt.Errorf("failed to compress %s: %v", src, err)
+	}
+
+	if err := gz.Close(); err != nil {
+		out.Close()
+		return fmt.Errorf("failed to finalize %s: %v", dst, err)
+	}
+
+	return out.Close()
+}
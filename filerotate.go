@@ -1,12 +1,44 @@
 package filerotate
 
 import (
+	"bufio"
 	"bytes"
 	"fmt"
+	"io"
 	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
 	"sync"
+	"time"
 )
 
+// RotationMode controls how time-based rotation aligns its deadlines.
+type RotationMode int
+
+const (
+	// RotationModeNone disables calendar-aligned rotation. Only Interval (if set) is used.
+	RotationModeNone RotationMode = iota
+	// RotationModeHourly rotates at the top of every hour.
+	RotationModeHourly
+	// RotationModeDaily rotates at midnight every day.
+	RotationModeDaily
+)
+
+// tickInterval is how often the background goroutine checks whether a
+// time-based rotation deadline has passed.
+const tickInterval = time.Second
+
+// DefaultLineScanLimit is the number of trailing bytes of a pending write
+// that are scanned for a LineSeparator before falling back to a plain byte
+// boundary split.
+const DefaultLineScanLimit = 16 * 1024
+
+// DefaultFlushInterval is how often a buffered Writer flushes pending bytes
+// to disk when BufferSize is set but FlushInterval is not.
+const DefaultFlushInterval = 100 * time.Millisecond
+
 // Options for the file rotation
 type Options struct {
 	// FilePath full path to the log file (i.e. our.log)
@@ -17,12 +49,61 @@ type Options struct {
 	Size int64
 	// File mode, like 0600
 	Mode os.FileMode
-	// LineSeparator is the separator for the rotated files content
-	// If specified, rotated files will be split only when the separator is found in the
-	// content of the file.
+	// LineSeparator is the separator for the rotated files content.
+	// If specified, once Size is exceeded, Write scans backward within the
+	// last LineScanLimit bytes of the pending write for a separator and
+	// splits there, so the archived file ends on a whole line.
 	LineSeparator []byte
+	// LineScanLimit bounds how many trailing bytes of a pending write are
+	// scanned for LineSeparator. Defaults to DefaultLineScanLimit. If no
+	// separator is found within the window, the write is split at the byte
+	// boundary instead of growing an unbounded buffer.
+	LineScanLimit int
+	// Interval rotates the file once it has been open longer than Interval.
+	// Zero disables interval-based rotation. Interval and RotationTime can be
+	// combined with Size: rotation happens on whichever condition hits first.
+	Interval time.Duration
+	// RotationTime aligns rotation to calendar boundaries (hourly/daily)
+	// instead of a plain countdown from file open time. When set, rotated
+	// files are named with a date suffix (e.g. test.log.2024-01-15) rather
+	// than the numeric .N scheme.
+	RotationTime RotationMode
+	// Compress enables gzip-style compression of rotated files, for both the
+	// numeric .N scheme and RotationTime's date-suffixed archives. Under the
+	// numeric scheme, the file is compressed one rotation after it is
+	// archived (filePath.1 is moved to filePath.2 and compressed into
+	// filePath.2.gz by the next rotation), so a file is never compressed while it could
+	// still be open for writing. Under RotationTime, each archive gets a
+	// unique date-suffixed name, so it's compressed right away instead.
+	Compress bool
+	// Compressor performs the compression of src into dst. Defaults to
+	// CompressGzip if Compress is true and Compressor is nil.
+	Compressor Compressor
+	// BufferSize enables a buffered write path: Write copies into an
+	// internal buffer of this size and returns immediately instead of
+	// writing straight through to the file. Zero disables buffering.
+	BufferSize int
+	// FlushInterval is how often buffered writes are flushed to disk when
+	// BufferSize is set. Defaults to DefaultFlushInterval if BufferSize is
+	// set and FlushInterval is zero. The buffer is also flushed whenever it
+	// fills, and on Close.
+	FlushInterval time.Duration
+	// OnError is called for failures that happen off the synchronous Write
+	// path and would otherwise be silently dropped: background rotation and
+	// compression, periodic flushing, and writes to the extra sinks passed
+	// to NewTeeWriter. Write's own return value is unaffected.
+	OnError func(error)
+	// FallbackWriter is used for writes whenever the primary file cannot be
+	// opened, at NewWriter or after a later rotation, e.g. because the disk
+	// is full. Once a rotation falls back it stays on FallbackWriter; there
+	// is no automatic attempt to reopen the primary file.
+	FallbackWriter io.Writer
 }
 
+// Compressor compresses src into dst. Implementations should not remove src;
+// the Writer removes it once dst has been written successfully.
+type Compressor func(src, dst string) error
+
 var (
 	LineSeparatorUnix    = []byte("\n")
 	LineSeparatorWindows = []byte("\r\n")
@@ -41,7 +122,23 @@ type Writer struct {
 	options Options
 	mu      sync.Mutex
 	f       *os.File // current file
-	buf     []byte   // buffer for the content during the search for the separator
+
+	fileStart    time.Time // when the current file was opened
+	nextRotation time.Time // next calendar-aligned rotation deadline, zero if RotationTime is unset
+	size         int64     // cached size of the current file, kept in sync on write/rotate to avoid a stat() per Write
+
+	buf *bufio.Writer // buffered write path when options.BufferSize > 0, nil otherwise
+
+	fallback io.Writer   // options.FallbackWriter, used in place of f once the primary file fails to open
+	extra    []io.Writer // additional sinks registered via NewTeeWriter, written alongside f
+
+	closed bool // set by Close, distinct from f == nil while running on fallback
+
+	compressDone chan struct{} // closed when the latest compressAsync call finishes; reset to nil only once rotateByCount has waited on it
+
+	tickerDone chan struct{}
+	flushDone  chan struct{}
+	wg         sync.WaitGroup
 }
 
 // NewWriter creates a new Writer
@@ -63,16 +160,253 @@ func NewWriter(options Options) (*Writer, error) {
 		options.Size = DefaultOptions.Size
 	}
 
+	if len(options.LineSeparator) > 0 && options.LineScanLimit == 0 {
+		options.LineScanLimit = DefaultLineScanLimit
+	}
+
+	if options.BufferSize > 0 && options.FlushInterval == 0 {
+		options.FlushInterval = DefaultFlushInterval
+	}
+
 	f, err := os.OpenFile(options.FilePath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, options.Mode)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create a new file: %v", err)
+		if options.FallbackWriter == nil {
+			return nil, fmt.Errorf("failed to create a new file: %v", err)
+		}
+
+		w := &Writer{options: options, fallback: options.FallbackWriter}
+		w.reportError(fmt.Errorf("failed to open %s, writing to the fallback instead: %v", options.FilePath, err))
+		return w, nil
+	}
+
+	stat, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, fmt.Errorf("failed to stat %s: %v", options.FilePath, err)
+	}
+
+	now := time.Now()
+
+	w := &Writer{
+		options:      options,
+		f:            f,
+		size:         stat.Size(),
+		fileStart:    now,
+		nextRotation: nextRotationDeadline(options.RotationTime, now),
+	}
+
+	if options.BufferSize > 0 {
+		w.buf = bufio.NewWriterSize(f, options.BufferSize)
 	}
 
-	return &Writer{
-		options: options,
-		f:       f,
-		buf:     make([]byte, 0),
-	}, nil
+	if err := w.recoverArchives(); err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	if options.Interval > 0 || options.RotationTime != RotationModeNone {
+		w.startTicker()
+	}
+
+	if options.BufferSize > 0 {
+		w.startFlushTicker()
+	}
+
+	return w, nil
+}
+
+// NewTeeWriter creates a Writer like NewWriter, but duplicates every write to
+// the given extra sinks (e.g. os.Stdout, a syslog writer, a network
+// collector) in addition to the rotating file, without running a second
+// rotation stack per sink. extra is written with the data passed to Write
+// before any splitting or rotation of the primary file; failures writing to
+// it are reported through Options.OnError rather than failing the call.
+func NewTeeWriter(options Options, extra ...io.Writer) (*Writer, error) {
+	w, err := NewWriter(options)
+	if err != nil {
+		return nil, err
+	}
+
+	w.extra = extra
+	return w, nil
+}
+
+// reportError invokes Options.OnError, if set, for failures that happen off
+// the synchronous Write path and would otherwise be silently dropped.
+func (w *Writer) reportError(err error) {
+	if err != nil && w.options.OnError != nil {
+		w.options.OnError(err)
+	}
+}
+
+// recoverArchives enumerates any filePath.N (and filePath.N.gz) siblings left
+// behind by a previous process, renumbering them contiguously in ascending
+// order and removing the highest-numbered ones beyond the first Rotate, so the "archives are contiguous .1..K with K <= Rotate" invariant
+// holds before the first rotation. A crash between rotations can otherwise
+// leave gaps or an out-of-range K, and the next rotation would either miss
+// files or clobber the wrong one. A filePath.N.compressing left behind by a
+// compressAsync call that was interrupted mid-compression is demoted back to
+// a plain filePath.N archive rather than treated as missing, so a crash
+// doesn't quietly shrink the surviving archive count below Rotate.
+func (w *Writer) recoverArchives() error {
+	if w.options.RotationTime != RotationModeNone {
+		// date-suffixed archives are self-describing, nothing to renumber
+		return nil
+	}
+
+	matches, err := filepath.Glob(w.options.FilePath + ".*")
+	if err != nil {
+		return fmt.Errorf("failed to list archives for %s: %v", w.options.FilePath, err)
+	}
+
+	re := regexp.MustCompile(`\.(\d+)(\.gz|\.compressing)?$`)
+
+	type archive struct {
+		n    int
+		path string
+		isGz bool
+	}
+
+	var archives []archive
+	for _, m := range matches {
+		sub := re.FindStringSubmatch(m)
+		if sub == nil {
+			continue
+		}
+
+		n, err := strconv.Atoi(sub[1])
+		if err != nil {
+			continue
+		}
+
+		archives = append(archives, archive{n: n, path: m, isGz: sub[2] == ".gz"})
+	}
+
+	sort.Slice(archives, func(i, j int) bool { return archives[i].n < archives[j].n })
+
+	next := 1
+	for _, a := range archives {
+		if next > w.options.Rotate {
+			if err := os.Remove(a.path); err != nil {
+				return fmt.Errorf("failed to remove %s: %v", a.path, err)
+			}
+			continue
+		}
+
+		newPath := fmt.Sprintf("%s.%d", w.options.FilePath, next)
+		if a.isGz {
+			newPath += ".gz"
+		}
+
+		if newPath != a.path {
+			if err := os.Rename(a.path, newPath); err != nil {
+				return fmt.Errorf("failed to rename %s to %s: %v", a.path, newPath, err)
+			}
+		}
+
+		next++
+	}
+
+	return nil
+}
+
+// nextRotationDeadline computes the next calendar-aligned rotation time for
+// the given mode, or the zero Time if mode is RotationModeNone.
+func nextRotationDeadline(mode RotationMode, from time.Time) time.Time {
+	switch mode {
+	case RotationModeHourly:
+		return from.Truncate(time.Hour).Add(time.Hour)
+	case RotationModeDaily:
+		y, m, d := from.Date()
+		return time.Date(y, m, d, 0, 0, 0, 0, from.Location()).AddDate(0, 0, 1)
+	default:
+		return time.Time{}
+	}
+}
+
+// archiveTimeLayout returns the time.Format layout used to name archived
+// files when RotationTime is set, or "" when numeric .N naming applies.
+func archiveTimeLayout(mode RotationMode) string {
+	switch mode {
+	case RotationModeHourly:
+		return "2006-01-02-15"
+	case RotationModeDaily:
+		return "2006-01-02"
+	default:
+		return ""
+	}
+}
+
+// startTicker launches the background goroutine that rotates idle writers
+// once a time-based deadline passes, even if Write is never called again.
+func (w *Writer) startTicker() {
+	w.tickerDone = make(chan struct{})
+	w.wg.Add(1)
+
+	go func() {
+		defer w.wg.Done()
+
+		ticker := time.NewTicker(tickInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				w.mu.Lock()
+				if w.f != nil && w.timeToRotate(time.Now()) {
+					if err := w.rotate(); err != nil {
+						w.reportError(err)
+					}
+				}
+				w.mu.Unlock()
+			case <-w.tickerDone:
+				return
+			}
+		}
+	}()
+}
+
+// startFlushTicker launches the background goroutine that periodically
+// flushes buffered writes to disk when BufferSize is set, so data isn't held
+// in memory for longer than FlushInterval between Write calls.
+func (w *Writer) startFlushTicker() {
+	w.flushDone = make(chan struct{})
+	w.wg.Add(1)
+
+	go func() {
+		defer w.wg.Done()
+
+		ticker := time.NewTicker(w.options.FlushInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				w.mu.Lock()
+				if w.buf != nil {
+					if err := w.buf.Flush(); err != nil {
+						w.reportError(fmt.Errorf("failed to flush buffered writes: %v", err))
+					}
+				}
+				w.mu.Unlock()
+			case <-w.flushDone:
+				return
+			}
+		}
+	}()
+}
+
+// timeToRotate reports whether a time-based rotation is due at now.
+func (w *Writer) timeToRotate(now time.Time) bool {
+	if w.options.Interval > 0 && now.Sub(w.fileStart) >= w.options.Interval {
+		return true
+	}
+
+	if w.options.RotationTime != RotationModeNone && !w.nextRotation.IsZero() && !now.Before(w.nextRotation) {
+		return true
+	}
+
+	return false
 }
 
 // Write writes the data to the file. If the file size exceeds the limit, it rotates the file.
@@ -81,21 +415,37 @@ func (w *Writer) Write(p []byte) (n int, err error) {
 	w.mu.Lock()
 	defer w.mu.Unlock()
 
-	if w.f == nil {
+	if w.closed {
 		return 0, fmt.Errorf("file is closed")
 	}
 
-	if w.options.Size == 0 {
-		return w.f.Write(p)
+	w.teeWrite(p)
+
+	if w.f == nil {
+		// the primary file failed to open or reopen and FallbackWriter is
+		// set; write() routes straight to it until the process is restarted
+		return w.write(p)
 	}
 
-	stat, err := w.f.Stat()
-	if err != nil {
-		return 0, err
+	if w.timeToRotate(time.Now()) {
+		if err := w.rotate(); err != nil {
+			return 0, err
+		}
+
+		if w.f == nil {
+			// rotate fell back to FallbackWriter; w.size no longer reflects
+			// anything meaningful, so skip the size check below rather than
+			// acting on its stale value
+			return w.write(p)
+		}
 	}
 
-	if stat.Size() < w.options.Size {
-		return w.f.Write(p)
+	if w.options.Size == 0 {
+		return w.write(p)
+	}
+
+	if w.size < w.options.Size {
+		return w.write(p)
 	}
 
 	// if LineSeparator is unset, rotate the file
@@ -104,58 +454,176 @@ func (w *Writer) Write(p []byte) (n int, err error) {
 			return 0, err
 		}
 
-		return w.f.Write(p)
+		return w.write(p)
 	}
 
-	// separator not yet found, memorize the content
-	w.buf = append(w.buf, p...)
-
-	// search for the separator in the buffer
-	loc := bytes.Index(w.buf, w.options.LineSeparator)
-	if loc == -1 {
-		return len(p), nil
-	}
+	// size exceeded: split p at the last separator within the scan window so
+	// the archived file ends on a whole line, falling back to a byte
+	// boundary split if no separator is found
+	prefix, suffix := splitOnLastSeparator(p, w.options.LineSeparator, w.options.LineScanLimit)
 
-	// separator found, write the content to the file
-	n0, err := w.f.Write(w.buf[:loc+len(w.options.LineSeparator)])
+	n0, err := w.write(prefix)
 	if err != nil {
-		return 0, err
+		return n0, err
 	}
 
-	// rotate the file
 	if err := w.rotate(); err != nil {
-		return 0, err
+		return n0, err
 	}
 
-	// write the rest of the buffer
-	n1, err := w.f.Write(w.buf[loc+len(w.options.LineSeparator):])
+	n1, err := w.write(suffix)
 	if err != nil {
-		return 0, err
+		return n0, err
 	}
 
-	// reset the buffer
-	w.buf = w.buf[:0]
-
 	return n0 + n1, nil
 
 }
 
+// write sends p to the current file, through the buffered writer if
+// BufferSize is set, and keeps size in sync so Write's size check never
+// needs to stat the file. If the primary file is unavailable, it falls back
+// to options.FallbackWriter instead.
+func (w *Writer) write(p []byte) (int, error) {
+	if w.f == nil {
+		if w.fallback == nil {
+			return 0, fmt.Errorf("file is closed")
+		}
+
+		return w.fallback.Write(p)
+	}
+
+	var n int
+	var err error
+
+	if w.buf != nil {
+		n, err = w.buf.Write(p)
+	} else {
+		n, err = w.f.Write(p)
+	}
+
+	w.size += int64(n)
+	return n, err
+}
+
+// teeWrite duplicates p to every extra sink registered via NewTeeWriter.
+// Failures are reported through Options.OnError rather than failing Write.
+func (w *Writer) teeWrite(p []byte) {
+	for _, e := range w.extra {
+		if _, err := e.Write(p); err != nil {
+			w.reportError(fmt.Errorf("failed to write to tee sink: %v", err))
+		}
+	}
+}
+
+// splitOnLastSeparator scans the last limit bytes of p for the last
+// occurrence of sep and splits p right after it. If sep is not found within
+// that window, it falls back to splitting at the edge of the window itself,
+// so at most limit bytes of p carry over into the next file; everything
+// before the split point still goes to the current file.
+func splitOnLastSeparator(p []byte, sep []byte, limit int) (prefix, suffix []byte) {
+	start := 0
+	if limit > 0 && len(p) > limit {
+		start = len(p) - limit
+	}
+
+	loc := bytes.LastIndex(p[start:], sep)
+	if loc == -1 {
+		return p[:start], p[start:]
+	}
+
+	splitAt := start + loc + len(sep)
+	return p[:splitAt], p[splitAt:]
+}
+
 func (w *Writer) rotate() error {
 
 	if w.f != nil {
+		if w.buf != nil {
+			if err := w.buf.Flush(); err != nil {
+				return fmt.Errorf("failed to flush buffered writes: %v", err)
+			}
+		}
+
 		err := w.f.Close()
 		if err != nil {
 			return fmt.Errorf("failed to close the file: %v", err)
 		}
 	}
 
+	if w.options.RotationTime != RotationModeNone {
+		if err := w.rotateByTime(); err != nil {
+			return err
+		}
+	} else {
+		if err := w.rotateByCount(); err != nil {
+			return err
+		}
+	}
+
+	// create a new file
+	f, err := os.OpenFile(w.options.FilePath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, w.options.Mode)
+	if err != nil {
+		if w.options.FallbackWriter == nil {
+			return fmt.Errorf("failed to create a new file: %v", err)
+		}
+
+		w.reportError(fmt.Errorf("failed to reopen %s after rotation, writing to the fallback instead: %v", w.options.FilePath, err))
+		w.f = nil
+		w.buf = nil
+		w.fallback = w.options.FallbackWriter
+		return nil
+	}
+
+	now := time.Now()
+	w.f = f
+	w.fileStart = now
+	w.nextRotation = nextRotationDeadline(w.options.RotationTime, now)
+	w.size = 0
+
+	if w.buf != nil {
+		w.buf = bufio.NewWriterSize(f, w.options.BufferSize)
+	}
+
+	return nil
+
+}
+
+// numberedArchive returns the path of filePath.N as it currently exists on
+// disk (plain, or .gz if Compress is in use and it has already been
+// compressed), and whether that existing path is the .gz variant.
+func (w *Writer) numberedArchive(n int) (path string, isGz bool) {
+	plain := fmt.Sprintf("%s.%d", w.options.FilePath, n)
+	gz := plain + ".gz"
+
+	if _, err := os.Stat(gz); err == nil {
+		return gz, true
+	}
+
+	return plain, false
+}
+
+// rotateByCount renames filePath to filePath.1, shifting filePath.1..N-1 up
+// by one and removing filePath.N, the existing numeric .N scheme. Archives
+// may be plain (filePath.N) or already compressed (filePath.N.gz).
+func (w *Writer) rotateByCount() error {
+
+	// wait for any compression left running by the previous rotation to
+	// finish first, so the shift loop below sees its .gz output and renumbers
+	// it along with everything else rather than racing a later compressAsync
+	// call for the same destination name
+	if w.compressDone != nil {
+		<-w.compressDone
+		w.compressDone = nil
+	}
+
 	// file named filePath.N where N is Rotate - is removed
 	// file named filePath.N-1 is renamed to filePath.N
 	// ...
 	// file named filePath is renamed to filePath.1
 
 	// remove the last file
-	removePath := fmt.Sprintf("%s.%d", w.options.FilePath, w.options.Rotate)
+	removePath, _ := w.numberedArchive(w.options.Rotate)
 	if _, err := os.Stat(removePath); err == nil {
 		err = os.Remove(removePath)
 		if err != nil {
@@ -164,7 +632,7 @@ func (w *Writer) rotate() error {
 	}
 
 	for i := w.options.Rotate - 1; i > 0; i-- {
-		oldPath := fmt.Sprintf("%s.%d", w.options.FilePath, i)
+		oldPath, oldIsGz := w.numberedArchive(i)
 
 		if _, err := os.Stat(oldPath); err != nil {
 			// file does not exist, skip
@@ -172,6 +640,10 @@ func (w *Writer) rotate() error {
 		}
 
 		newPath := fmt.Sprintf("%s.%d", w.options.FilePath, i+1)
+		if oldIsGz {
+			newPath += ".gz"
+		}
+
 		err := os.Rename(oldPath, newPath)
 		if err != nil {
 			return fmt.Errorf("failed to rename %s to %s: %v", oldPath, newPath, err)
@@ -184,22 +656,162 @@ func (w *Writer) rotate() error {
 		return fmt.Errorf("failed to rename %s to %s: %v", w.options.FilePath, w.options.FilePath+".1", err)
 	}
 
-	// create a new file
-	f, err := os.OpenFile(w.options.FilePath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, w.options.Mode)
+	if w.options.Compress {
+		// the file that was at .1 before this rotation is now at .2; it has
+		// been closed since the previous rotation, so it's safe to compress
+		w.compressAsync(fmt.Sprintf("%s.2", w.options.FilePath))
+	}
+
+	return nil
+}
+
+// compressAsync compresses path into path+".gz" in a background goroutine
+// tracked by w.wg. It is a no-op if path does not exist (nothing to compress
+// yet) or is already compressed. path is renamed aside synchronously before
+// the goroutine starts, so it's immediately free for a subsequent rotation
+// to reuse rather than racing with the background compression. Callers must
+// ensure no earlier compression of the same path is still running before
+// calling this again; rotateByCount waits on compressDone for that, while
+// rotateByTime's archive names never repeat.
+// If the compressor fails, the renamed-aside file is restored to path rather
+// than abandoned, so the archive stays live in the rotation sequence instead
+// of silently disappearing from the Rotate count.
+func (w *Writer) compressAsync(path string) {
+	tmp := path + ".compressing"
+	if err := os.Rename(path, tmp); err != nil {
+		return
+	}
+
+	compressor := w.options.Compressor
+	if compressor == nil {
+		compressor = CompressGzip
+	}
+
+	done := make(chan struct{})
+	w.compressDone = done
+
+	w.wg.Add(1)
+	go func() {
+		defer w.wg.Done()
+		defer close(done)
+
+		if err := compressor(tmp, path+".gz"); err != nil {
+			w.reportError(fmt.Errorf("failed to compress %s: %v", tmp, err))
+
+			if restoreErr := os.Rename(tmp, path); restoreErr != nil {
+				w.reportError(fmt.Errorf("failed to restore %s after failed compression: %v", path, restoreErr))
+			}
+
+			return
+		}
+
+		if err := os.Remove(tmp); err != nil {
+			w.reportError(fmt.Errorf("failed to remove %s after compression: %v", tmp, err))
+		}
+	}()
+}
+
+// rotateByTime renames filePath to filePath.<date>, using a date suffix
+// derived from RotationTime instead of the numeric .N scheme, and prunes
+// the oldest archives beyond Rotate count.
+func (w *Writer) rotateByTime() error {
+
+	archivePath := fmt.Sprintf("%s.%s", w.options.FilePath, time.Now().Format(archiveTimeLayout(w.options.RotationTime)))
+
+	// avoid clobbering an archive from an earlier rotation within the same
+	// period, whether it is still plain, being compressed, or compressed
+	for i := 1; ; i++ {
+		_, errPlain := os.Stat(archivePath)
+		_, errTmp := os.Stat(archivePath + ".compressing")
+		_, errGz := os.Stat(archivePath + ".gz")
+		if os.IsNotExist(errPlain) && os.IsNotExist(errTmp) && os.IsNotExist(errGz) {
+			break
+		}
+		archivePath = fmt.Sprintf("%s.%s-%d", w.options.FilePath, time.Now().Format(archiveTimeLayout(w.options.RotationTime)), i)
+	}
+
+	if err := os.Rename(w.options.FilePath, archivePath); err != nil {
+		return fmt.Errorf("failed to rename %s to %s: %v", w.options.FilePath, archivePath, err)
+	}
+
+	matches, err := filepath.Glob(w.options.FilePath + ".*")
 	if err != nil {
-		return fmt.Errorf("failed to create a new file: %v", err)
+		return fmt.Errorf("failed to list archives for %s: %v", w.options.FilePath, err)
+	}
+
+	if len(matches) > w.options.Rotate {
+		// matches from filepath.Glob are already sorted lexically, which for
+		// our date-based suffixes also means sorted oldest-first
+		for _, old := range matches[:len(matches)-w.options.Rotate] {
+			if err := os.Remove(old); err != nil {
+				return fmt.Errorf("failed to remove %s: %v", old, err)
+			}
+		}
+	}
+
+	if w.options.Compress {
+		// archivePath is a freshly created, uniquely named file that was
+		// closed before this rotation started, so unlike the numeric .N
+		// scheme there's no slot-reuse race to wait out: compress it right
+		// away rather than delaying a generation
+		w.compressAsync(archivePath)
 	}
 
-	w.f = f
 	return nil
+}
+
+// Rotate forces an immediate rotation regardless of Size, Interval, or
+// RotationTime, e.g. in response to a SIGHUP for logrotate-style
+// copytruncate/postrotate integrations.
+func (w *Writer) Rotate() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.closed {
+		return fmt.Errorf("file is closed")
+	}
+
+	if w.f == nil {
+		return fmt.Errorf("no primary file to rotate, writer is running on the fallback")
+	}
 
+	return w.rotate()
 }
 
-// Close closes the file
+// Close stops the background goroutines, flushes any buffered writes, and
+// closes the file.
 func (w *Writer) Close() error {
+	w.mu.Lock()
+
+	if w.closed {
+		w.mu.Unlock()
+		return nil
+	}
+	w.closed = true
+
+	if w.tickerDone != nil {
+		close(w.tickerDone)
+	}
+	if w.flushDone != nil {
+		close(w.flushDone)
+	}
+	w.mu.Unlock()
+
+	w.wg.Wait()
+
 	w.mu.Lock()
 	defer w.mu.Unlock()
 
+	if w.buf != nil {
+		if err := w.buf.Flush(); err != nil {
+			if w.f != nil {
+				w.f.Close()
+				w.f = nil
+			}
+			return fmt.Errorf("failed to flush buffered writes: %v", err)
+		}
+	}
+
 	if w.f != nil {
 		err := w.f.Close()
 		w.f = nil
@@ -1,10 +1,12 @@
 package filerotate
 
 import (
+	"bytes"
 	"fmt"
 	"os"
 	"path"
 	"testing"
+	"time"
 )
 
 func TestNewWriter(t *testing.T) {
@@ -171,3 +173,641 @@ func TestWriterRotatesOnSeparator(t *testing.T) {
 	}
 
 }
+
+func TestWriterRotatesOnInterval(t *testing.T) {
+	// tmp dir:
+	basePath, err := os.MkdirTemp("", "filerotate-test-*")
+	if err != nil {
+		t.Fatalf("failed to create a temp dir: %v", err)
+	}
+
+	w, err := NewWriter(Options{
+		FilePath: path.Join(basePath, "test.log"),
+		Rotate:   5,
+		Size:     1000,
+		Mode:     0644,
+		Interval: 300 * time.Millisecond,
+	})
+
+	if err != nil {
+		t.Fatalf("failed to create a new writer: %v", err)
+	}
+
+	defer w.Close()
+
+	if _, err := w.Write([]byte("test")); err != nil {
+		t.Fatalf("failed to write to the file: %v", err)
+	}
+
+	// the background ticker should rotate the idle writer once Interval has passed,
+	// without any further Write calls
+	time.Sleep(1500 * time.Millisecond)
+
+	if _, err := os.Stat(path.Join(basePath, "test.log.1")); err != nil {
+		t.Fatalf("expected test.log.1 to exist after interval elapsed: %v", err)
+	}
+}
+
+func TestWriterRotatesDailyWithDateSuffix(t *testing.T) {
+	// tmp dir:
+	basePath, err := os.MkdirTemp("", "filerotate-test-*")
+	if err != nil {
+		t.Fatalf("failed to create a temp dir: %v", err)
+	}
+
+	w, err := NewWriter(Options{
+		FilePath:     path.Join(basePath, "test.log"),
+		Rotate:       5,
+		Size:         1000,
+		Mode:         0644,
+		RotationTime: RotationModeDaily,
+	})
+
+	if err != nil {
+		t.Fatalf("failed to create a new writer: %v", err)
+	}
+
+	if _, err := w.Write([]byte("test")); err != nil {
+		t.Fatalf("failed to write to the file: %v", err)
+	}
+
+	if err := w.rotate(); err != nil {
+		t.Fatalf("failed to rotate the file: %v", err)
+	}
+
+	err = w.Close()
+	if err != nil {
+		t.Fatalf("failed to close the writer: %v", err)
+	}
+
+	wantName := "test.log." + time.Now().Format("2006-01-02")
+	if _, err := os.Stat(path.Join(basePath, wantName)); err != nil {
+		t.Fatalf("expected %s to exist after daily rotation: %v", wantName, err)
+	}
+}
+
+func TestWriterCompressesDateSuffixedArchive(t *testing.T) {
+	// tmp dir:
+	basePath, err := os.MkdirTemp("", "filerotate-test-*")
+	if err != nil {
+		t.Fatalf("failed to create a temp dir: %v", err)
+	}
+
+	w, err := NewWriter(Options{
+		FilePath:     path.Join(basePath, "test.log"),
+		Rotate:       5,
+		Size:         1000,
+		Mode:         0644,
+		RotationTime: RotationModeDaily,
+		Compress:     true,
+	})
+
+	if err != nil {
+		t.Fatalf("failed to create a new writer: %v", err)
+	}
+
+	if _, err := w.Write([]byte("test")); err != nil {
+		t.Fatalf("failed to write to the file: %v", err)
+	}
+
+	if err := w.rotate(); err != nil {
+		t.Fatalf("failed to rotate the file: %v", err)
+	}
+
+	err = w.Close()
+	if err != nil {
+		t.Fatalf("failed to close the writer: %v", err)
+	}
+
+	wantName := "test.log." + time.Now().Format("2006-01-02") + ".gz"
+	if _, err := os.Stat(path.Join(basePath, wantName)); err != nil {
+		t.Fatalf("expected %s to exist after a compressed daily rotation: %v", wantName, err)
+	}
+}
+
+func TestWriterCompressesOlderArchive(t *testing.T) {
+	// tmp dir:
+	basePath, err := os.MkdirTemp("", "filerotate-test-*")
+	if err != nil {
+		t.Fatalf("failed to create a temp dir: %v", err)
+	}
+
+	w, err := NewWriter(Options{
+		FilePath: path.Join(basePath, "test.log"),
+		Rotate:   5,
+		Size:     1000,
+		Mode:     0644,
+		Compress: true,
+	})
+
+	if err != nil {
+		t.Fatalf("failed to create a new writer: %v", err)
+	}
+
+	// three rotations: test.log.1 is created, then shifted to .2 (and
+	// compressed) on the second rotation, then shifted again to .3 on the
+	// third rotation, as .2 fills up with the next archive in turn
+	for i := 0; i < 3; i++ {
+		if err := w.rotate(); err != nil {
+			t.Fatalf("failed to rotate the file: %v", err)
+		}
+	}
+
+	err = w.Close()
+	if err != nil {
+		t.Fatalf("failed to close the writer: %v", err)
+	}
+
+	if _, err := os.Stat(path.Join(basePath, "test.log.3.gz")); err != nil {
+		t.Fatalf("expected test.log.3.gz to exist after compression: %v", err)
+	}
+
+	if _, err := os.Stat(path.Join(basePath, "test.log.2.gz")); err != nil {
+		t.Fatalf("expected test.log.2.gz to exist after compression: %v", err)
+	}
+
+	if _, err := os.Stat(path.Join(basePath, "test.log.2")); !os.IsNotExist(err) {
+		t.Fatalf("expected uncompressed test.log.2 to be removed, stat err: %v", err)
+	}
+}
+
+func TestWriterKeepsArchiveCountWhenCompressionFails(t *testing.T) {
+	// tmp dir:
+	basePath, err := os.MkdirTemp("", "filerotate-test-*")
+	if err != nil {
+		t.Fatalf("failed to create a temp dir: %v", err)
+	}
+
+	w, err := NewWriter(Options{
+		FilePath: path.Join(basePath, "test.log"),
+		Rotate:   10,
+		Size:     1000,
+		Mode:     0644,
+		Compress: true,
+		Compressor: func(src, dst string) error {
+			return fmt.Errorf("compressor is broken")
+		},
+	})
+
+	if err != nil {
+		t.Fatalf("failed to create a new writer: %v", err)
+	}
+
+	for i := 0; i < 8; i++ {
+		if err := w.rotate(); err != nil {
+			t.Fatalf("failed to rotate the file: %v", err)
+		}
+	}
+
+	err = w.Close()
+	if err != nil {
+		t.Fatalf("failed to close the writer: %v", err)
+	}
+
+	// a failed compression must leave the archive live in the rotation
+	// sequence rather than abandoned as a .compressing leftover; with 8
+	// rotations and Rotate: 10, archives .1 through .8 must all survive
+	for i := 1; i <= 8; i++ {
+		name := fmt.Sprintf("test.log.%d", i)
+		if _, err := os.Stat(path.Join(basePath, name)); err != nil {
+			t.Fatalf("expected %s to survive a failed compression, stat err: %v", name, err)
+		}
+	}
+
+	if _, err := os.Stat(path.Join(basePath, "test.log.2.compressing")); !os.IsNotExist(err) {
+		t.Fatalf("expected no orphaned .compressing file, stat err: %v", err)
+	}
+}
+
+func TestNewWriterRecoversCompressingLeftoverOnRestart(t *testing.T) {
+	// tmp dir:
+	basePath, err := os.MkdirTemp("", "filerotate-test-*")
+	if err != nil {
+		t.Fatalf("failed to create a temp dir: %v", err)
+	}
+
+	filePath := path.Join(basePath, "test.log")
+
+	// simulate a crash mid-compression: .1 is a normal archive, .2 was
+	// renamed aside for compression and never finished
+	if err := os.WriteFile(path.Join(basePath, "test.log.1"), []byte("test.log.1"), 0644); err != nil {
+		t.Fatalf("failed to seed test.log.1: %v", err)
+	}
+	if err := os.WriteFile(path.Join(basePath, "test.log.2.compressing"), []byte("test.log.2"), 0644); err != nil {
+		t.Fatalf("failed to seed test.log.2.compressing: %v", err)
+	}
+
+	w, err := NewWriter(Options{
+		FilePath: filePath,
+		Rotate:   5,
+		Size:     1000,
+		Mode:     0644,
+		Compress: true,
+	})
+
+	if err != nil {
+		t.Fatalf("failed to create a new writer: %v", err)
+	}
+
+	defer w.Close()
+
+	if _, err := os.Stat(path.Join(basePath, "test.log.2.compressing")); !os.IsNotExist(err) {
+		t.Fatalf("expected the .compressing leftover to be recovered, stat err: %v", err)
+	}
+
+	got, err := os.ReadFile(path.Join(basePath, "test.log.2"))
+	if err != nil || string(got) != "test.log.2" {
+		t.Fatalf("expected test.log.2.compressing to be restored as plain test.log.2, got %q, err %v", got, err)
+	}
+}
+
+func TestWriterSplitsLargeWriteWithinScanWindow(t *testing.T) {
+	// tmp dir:
+	basePath, err := os.MkdirTemp("", "filerotate-test-*")
+	if err != nil {
+		t.Fatalf("failed to create a temp dir: %v", err)
+	}
+
+	w, err := NewWriter(Options{
+		FilePath:      path.Join(basePath, "test.log"),
+		Rotate:        5,
+		Size:          10,
+		Mode:          0644,
+		LineSeparator: LineSeparatorUnix,
+		LineScanLimit: 1024,
+	})
+
+	if err != nil {
+		t.Fatalf("failed to create a new writer: %v", err)
+	}
+
+	// cross the Size threshold first
+	if _, err := w.Write([]byte("12345678901\n")); err != nil {
+		t.Fatalf("failed to write to the file: %v", err)
+	}
+
+	// a single large write containing a separator near the end; it should be
+	// split at that separator rather than buffered indefinitely
+	big := append(bytes.Repeat([]byte("x"), 100), []byte("tail\n")...)
+	if _, err := w.Write(big); err != nil {
+		t.Fatalf("failed to write to the file: %v", err)
+	}
+
+	err = w.Close()
+	if err != nil {
+		t.Fatalf("failed to close the writer: %v", err)
+	}
+
+	got, err := os.ReadFile(path.Join(basePath, "test.log.1"))
+	if err != nil {
+		t.Fatalf("failed to read test.log.1: %v", err)
+	}
+
+	if !bytes.HasSuffix(got, []byte("tail\n")) {
+		t.Fatalf("expected test.log.1 to end with a whole line, got: %q", got)
+	}
+}
+
+func TestWriterFallsBackToByteSplitWithoutSeparator(t *testing.T) {
+	// tmp dir:
+	basePath, err := os.MkdirTemp("", "filerotate-test-*")
+	if err != nil {
+		t.Fatalf("failed to create a temp dir: %v", err)
+	}
+
+	w, err := NewWriter(Options{
+		FilePath:      path.Join(basePath, "test.log"),
+		Rotate:        5,
+		Size:          10,
+		Mode:          0644,
+		LineSeparator: LineSeparatorUnix,
+		LineScanLimit: 16,
+	})
+
+	if err != nil {
+		t.Fatalf("failed to create a new writer: %v", err)
+	}
+
+	// cross the Size threshold first
+	if _, err := w.Write([]byte("12345678901\n")); err != nil {
+		t.Fatalf("failed to write to the file: %v", err)
+	}
+
+	// no separator anywhere in this write, and it's longer than LineScanLimit:
+	// it must not be buffered forever, so it's split at the edge of the scan
+	// window instead, bounding how much of it carries over into the new file
+	noSep := bytes.Repeat([]byte("x"), 64)
+	n, err := w.Write(noSep)
+	if err != nil {
+		t.Fatalf("failed to write to the file: %v", err)
+	}
+
+	if n != len(noSep) {
+		t.Fatalf("expected all %d bytes to be accounted for, got %d", len(noSep), n)
+	}
+
+	err = w.Close()
+	if err != nil {
+		t.Fatalf("failed to close the writer: %v", err)
+	}
+
+	// the first len(noSep)-LineScanLimit bytes go to the file being rotated
+	// away, ahead of the split at the edge of the scan window
+	archived, err := os.ReadFile(path.Join(basePath, "test.log.1"))
+	if err != nil {
+		t.Fatalf("failed to read test.log.1: %v", err)
+	}
+
+	wantArchived := bytes.Repeat([]byte("x"), len(noSep)-16)
+	if !bytes.HasSuffix(archived, wantArchived) {
+		t.Fatalf("expected test.log.1 to end with %q, got %q", wantArchived, archived)
+	}
+
+	// the trailing LineScanLimit bytes carry over into the new file
+	current, err := os.ReadFile(path.Join(basePath, "test.log"))
+	if err != nil {
+		t.Fatalf("failed to read test.log: %v", err)
+	}
+
+	wantCurrent := bytes.Repeat([]byte("x"), 16)
+	if string(current) != string(wantCurrent) {
+		t.Fatalf("expected test.log to hold the trailing %q, got %q", wantCurrent, current)
+	}
+}
+
+func TestNewWriterRenumbersStaleArchivesOnRestart(t *testing.T) {
+	// tmp dir:
+	basePath, err := os.MkdirTemp("", "filerotate-test-*")
+	if err != nil {
+		t.Fatalf("failed to create a temp dir: %v", err)
+	}
+
+	filePath := path.Join(basePath, "test.log")
+
+	// simulate leftovers from a previous process: a gap at .2, and an
+	// out-of-range .7 with Rotate set to 5
+	for _, name := range []string{"test.log.1", "test.log.3", "test.log.7"} {
+		if err := os.WriteFile(path.Join(basePath, name), []byte(name), 0644); err != nil {
+			t.Fatalf("failed to seed %s: %v", name, err)
+		}
+	}
+
+	w, err := NewWriter(Options{
+		FilePath: filePath,
+		Rotate:   5,
+		Size:     1000,
+		Mode:     0644,
+	})
+
+	if err != nil {
+		t.Fatalf("failed to create a new writer: %v", err)
+	}
+
+	defer w.Close()
+
+	// .7 is beyond Rotate and must not stay there; .1, .3 and .7 must be
+	// renumbered contiguously to .1, .2 and .3 with no gap, preserving their
+	// relative order
+	if _, err := os.Stat(path.Join(basePath, "test.log.7")); !os.IsNotExist(err) {
+		t.Fatalf("expected out-of-range test.log.7 to be renumbered, stat err: %v", err)
+	}
+
+	got, err := os.ReadFile(path.Join(basePath, "test.log.1"))
+	if err != nil || string(got) != "test.log.1" {
+		t.Fatalf("expected test.log.1 to still hold the newest archive, got %q, err %v", got, err)
+	}
+
+	got, err = os.ReadFile(path.Join(basePath, "test.log.2"))
+	if err != nil || string(got) != "test.log.3" {
+		t.Fatalf("expected test.log.3 to be renumbered to test.log.2, got %q, err %v", got, err)
+	}
+}
+
+func TestWriterBuffersAndFlushesOnInterval(t *testing.T) {
+	// tmp dir:
+	basePath, err := os.MkdirTemp("", "filerotate-test-*")
+	if err != nil {
+		t.Fatalf("failed to create a temp dir: %v", err)
+	}
+
+	filePath := path.Join(basePath, "test.log")
+
+	w, err := NewWriter(Options{
+		FilePath:      filePath,
+		Rotate:        5,
+		Size:          1000,
+		Mode:          0644,
+		BufferSize:    1024,
+		FlushInterval: 100 * time.Millisecond,
+	})
+
+	if err != nil {
+		t.Fatalf("failed to create a new writer: %v", err)
+	}
+
+	defer w.Close()
+
+	if _, err := w.Write([]byte("test")); err != nil {
+		t.Fatalf("failed to write to the file: %v", err)
+	}
+
+	// the write should sit in the buffer rather than hit disk immediately
+	got, err := os.ReadFile(filePath)
+	if err != nil {
+		t.Fatalf("failed to read the file: %v", err)
+	}
+
+	if len(got) != 0 {
+		t.Fatalf("expected the buffered write not to be on disk yet, got %q", got)
+	}
+
+	// the background ticker should flush it once FlushInterval has passed
+	time.Sleep(500 * time.Millisecond)
+
+	got, err = os.ReadFile(filePath)
+	if err != nil {
+		t.Fatalf("failed to read the file: %v", err)
+	}
+
+	if string(got) != "test" {
+		t.Fatalf("expected the buffer to be flushed to disk, got %q", got)
+	}
+}
+
+func TestWriterFlushesBufferOnClose(t *testing.T) {
+	// tmp dir:
+	basePath, err := os.MkdirTemp("", "filerotate-test-*")
+	if err != nil {
+		t.Fatalf("failed to create a temp dir: %v", err)
+	}
+
+	filePath := path.Join(basePath, "test.log")
+
+	w, err := NewWriter(Options{
+		FilePath:   filePath,
+		Rotate:     5,
+		Size:       1000,
+		Mode:       0644,
+		BufferSize: 1024,
+	})
+
+	if err != nil {
+		t.Fatalf("failed to create a new writer: %v", err)
+	}
+
+	if _, err := w.Write([]byte("test")); err != nil {
+		t.Fatalf("failed to write to the file: %v", err)
+	}
+
+	if err := w.Close(); err != nil {
+		t.Fatalf("failed to close the writer: %v", err)
+	}
+
+	got, err := os.ReadFile(filePath)
+	if err != nil {
+		t.Fatalf("failed to read the file: %v", err)
+	}
+
+	if string(got) != "test" {
+		t.Fatalf("expected the buffer to be flushed on close, got %q", got)
+	}
+}
+
+func TestNewTeeWriterDuplicatesWrites(t *testing.T) {
+	// tmp dir:
+	basePath, err := os.MkdirTemp("", "filerotate-test-*")
+	if err != nil {
+		t.Fatalf("failed to create a temp dir: %v", err)
+	}
+
+	var extra bytes.Buffer
+
+	w, err := NewTeeWriter(Options{
+		FilePath: path.Join(basePath, "test.log"),
+		Rotate:   5,
+		Size:     1000,
+		Mode:     0644,
+	}, &extra)
+
+	if err != nil {
+		t.Fatalf("failed to create a new writer: %v", err)
+	}
+
+	if _, err := w.Write([]byte("test")); err != nil {
+		t.Fatalf("failed to write to the file: %v", err)
+	}
+
+	if err := w.Close(); err != nil {
+		t.Fatalf("failed to close the writer: %v", err)
+	}
+
+	if extra.String() != "test" {
+		t.Fatalf("expected the tee sink to receive the write, got %q", extra.String())
+	}
+
+	got, err := os.ReadFile(path.Join(basePath, "test.log"))
+	if err != nil {
+		t.Fatalf("failed to read the file: %v", err)
+	}
+
+	if string(got) != "test" {
+		t.Fatalf("expected the primary file to still receive the write, got %q", got)
+	}
+}
+
+func TestWriterFallsBackWhenPrimaryFileCannotBeOpened(t *testing.T) {
+	var fallback bytes.Buffer
+	var reported error
+
+	w, err := NewWriter(Options{
+		FilePath:       path.Join("/does/not/exist", "test.log"),
+		Rotate:         5,
+		Size:           1000,
+		Mode:           0644,
+		FallbackWriter: &fallback,
+		OnError:        func(err error) { reported = err },
+	})
+
+	if err != nil {
+		t.Fatalf("expected NewWriter to succeed on the fallback, got: %v", err)
+	}
+
+	if reported == nil {
+		t.Fatalf("expected OnError to be called with the open failure")
+	}
+
+	if _, err := w.Write([]byte("test")); err != nil {
+		t.Fatalf("failed to write to the fallback: %v", err)
+	}
+
+	if err := w.Close(); err != nil {
+		t.Fatalf("failed to close the writer: %v", err)
+	}
+
+	if fallback.String() != "test" {
+		t.Fatalf("expected the write to land on the fallback, got %q", fallback.String())
+	}
+}
+
+func TestWriterCloseIsIdempotent(t *testing.T) {
+	// tmp dir:
+	basePath, err := os.MkdirTemp("", "filerotate-test-*")
+	if err != nil {
+		t.Fatalf("failed to create a temp dir: %v", err)
+	}
+
+	w, err := NewWriter(Options{
+		FilePath: path.Join(basePath, "test.log"),
+		Rotate:   5,
+		Size:     1000,
+		Mode:     0644,
+	})
+
+	if err != nil {
+		t.Fatalf("failed to create a new writer: %v", err)
+	}
+
+	if err := w.Close(); err != nil {
+		t.Fatalf("failed to close the writer: %v", err)
+	}
+
+	if err := w.Close(); err != nil {
+		t.Fatalf("expected a second Close to be a no-op, got: %v", err)
+	}
+}
+
+func TestWriterRotateForcesImmediateRotation(t *testing.T) {
+	// tmp dir:
+	basePath, err := os.MkdirTemp("", "filerotate-test-*")
+	if err != nil {
+		t.Fatalf("failed to create a temp dir: %v", err)
+	}
+
+	w, err := NewWriter(Options{
+		FilePath: path.Join(basePath, "test.log"),
+		Rotate:   5,
+		Size:     1000,
+		Mode:     0644,
+	})
+
+	if err != nil {
+		t.Fatalf("failed to create a new writer: %v", err)
+	}
+
+	if _, err := w.Write([]byte("test")); err != nil {
+		t.Fatalf("failed to write to the file: %v", err)
+	}
+
+	if err := w.Rotate(); err != nil {
+		t.Fatalf("failed to force rotation: %v", err)
+	}
+
+	err = w.Close()
+	if err != nil {
+		t.Fatalf("failed to close the writer: %v", err)
+	}
+
+	if _, err := os.Stat(path.Join(basePath, "test.log.1")); err != nil {
+		t.Fatalf("expected test.log.1 to exist after a forced rotation: %v", err)
+	}
+}